@@ -0,0 +1,192 @@
+// Package archive persists every page a scrape fetches as a WARC record and
+// keeps a durable record of which URLs a task has already visited, so a
+// killed-and-restarted scrape can resume without re-hitting origins and a
+// completed one can be re-extracted later without a live fetch.
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var seenBucket = []byte("seen")
+var metaBucket = []byte("meta")
+
+// nextURLKey stores the URL Run should fetch next when this task resumes,
+// so a kill-and-restart of a paginated crawl continues from where it left
+// off instead of only skipping pages already in the seen-set.
+const nextURLKey = "next_url"
+
+// Archive writes fetched pages to a rotating WARC file and records visited
+// URLs (and a content hash, to detect a changed page on re-fetch) in a
+// persistent key/value store keyed by task ID.
+type Archive struct {
+	// MaxFileSize rotates to a new WARC file once the current one grows
+	// past this many bytes. Zero disables rotation.
+	MaxFileSize int64
+
+	taskID string
+	dir    string
+
+	mu       sync.Mutex
+	db       *bolt.DB
+	warc     *os.File
+	warcSize int64
+	warcPart int
+}
+
+// Open creates or reopens the archive for taskID under dir, reloading its
+// seen-set from a previous run if one exists. The caller should pass the
+// same dir/taskID pair across a kill-and-restart so the seen-set and WARC
+// file are resumed rather than started fresh.
+func Open(dir, taskID string) (*Archive, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("archive: creating %s: %w", dir, err)
+	}
+
+	db, err := bolt.Open(filepath.Join(dir, taskID+".db"), 0o644, nil)
+	if err != nil {
+		return nil, fmt.Errorf("archive: opening seen-set for task %s: %w", taskID, err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(seenBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(metaBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	a := &Archive{taskID: taskID, dir: dir, db: db}
+
+	// Resume appending to the highest-numbered existing WARC part instead
+	// of truncating it.
+	matches, _ := filepath.Glob(filepath.Join(dir, taskID+"-*.warc"))
+	if len(matches) > 0 {
+		a.warcPart = len(matches) - 1
+	}
+	if err := a.openWARCPart(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (a *Archive) openWARCPart() error {
+	path := filepath.Join(a.dir, fmt.Sprintf("%s-%05d.warc", a.taskID, a.warcPart))
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("archive: opening %s: %w", path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	a.warc = f
+	a.warcSize = info.Size()
+	return nil
+}
+
+// Seen reports whether url has already been recorded for this task.
+func (a *Archive) Seen(url string) (bool, error) {
+	var seen bool
+	err := a.db.View(func(tx *bolt.Tx) error {
+		seen = tx.Bucket(seenBucket).Get([]byte(url)) != nil
+		return nil
+	})
+	return seen, err
+}
+
+// SetNextURL records rawURL as the page Run should fetch next if this task
+// is killed and resumed, so a paginated crawl can continue past its
+// listing's first page instead of stopping as soon as that page is found
+// already seen. An empty rawURL marks the crawl as having reached its last
+// page.
+func (a *Archive) SetNextURL(rawURL string) error {
+	return a.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBucket).Put([]byte(nextURLKey), []byte(rawURL))
+	})
+}
+
+// NextURL returns the URL a previous SetNextURL call recorded, or "" if
+// none has been recorded yet - the case for a task that hasn't started or
+// has never been resumed.
+func (a *Archive) NextURL() (string, error) {
+	var url string
+	err := a.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(metaBucket).Get([]byte(nextURLKey)); v != nil {
+			url = string(v)
+		}
+		return nil
+	})
+	return url, err
+}
+
+// Record writes req/resp as a WARC 1.1 request+response pair and marks
+// req's URL as seen with the response body's content hash. Once the write
+// brings the current WARC part to MaxFileSize or beyond, it rotates to a
+// new part for the next Record.
+func (a *Archive) Record(req *http.Request, resp *http.Response, body []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	n, err := a.writeWARCRecords(req, resp, body)
+	if err != nil {
+		return err
+	}
+	a.warcSize += n
+
+	if a.MaxFileSize > 0 && a.warcSize >= a.MaxFileSize {
+		if err := a.warc.Close(); err != nil {
+			return err
+		}
+		a.warcPart++
+		if err := a.openWARCPart(); err != nil {
+			return err
+		}
+	}
+
+	hash := sha256.Sum256(body)
+	return a.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(seenBucket).Put([]byte(req.URL.String()), []byte(hex.EncodeToString(hash[:])))
+	})
+}
+
+func (a *Archive) writeWARCRecords(req *http.Request, resp *http.Response, body []byte) (int64, error) {
+	reqRecord := newWARCRequestRecord(req)
+	respRecord := newWARCResponseRecord(resp, body)
+
+	var total int64
+	for _, rec := range [][]byte{reqRecord, respRecord} {
+		n, err := a.warc.Write(rec)
+		total += int64(n)
+		if err != nil {
+			return total, fmt.Errorf("archive: writing WARC record: %w", err)
+		}
+	}
+	return total, nil
+}
+
+// Close closes the seen-set store and the current WARC file.
+func (a *Archive) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	warcErr := a.warc.Close()
+	dbErr := a.db.Close()
+	if warcErr != nil {
+		return warcErr
+	}
+	return dbErr
+}
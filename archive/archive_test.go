@@ -0,0 +1,107 @@
+package archive
+
+import (
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveRecordAndReplayRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	a, err := Open(dir, "test-task")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	u, _ := url.Parse("https://example.com/page")
+	req := &http.Request{Method: "GET", URL: u, Header: http.Header{}}
+	resp := &http.Response{
+		Status:     "200 OK",
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{"text/html"}},
+		Request:    req,
+	}
+	body := []byte("<html><body><p>hello</p></body></html>")
+
+	if err := a.Record(req, resp, body); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	seen, err := a.Seen("https://example.com/page")
+	if err != nil {
+		t.Fatalf("Seen: %v", err)
+	}
+	if !seen {
+		t.Fatal("expected URL to be marked seen after Record")
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "test-task-*.warc"))
+	if len(matches) != 1 {
+		t.Fatalf("expected exactly one WARC part, got %d: %v", len(matches), matches)
+	}
+
+	records, err := ReadRecords(matches[0])
+	if err != nil {
+		t.Fatalf("ReadRecords: %v", err)
+	}
+
+	var gotResponse bool
+	for _, rec := range records {
+		if rec.Type != "response" {
+			continue
+		}
+		gotResponse = true
+		respBody, err := ResponseBody(rec)
+		if err != nil {
+			t.Fatalf("ResponseBody: %v", err)
+		}
+		if string(respBody) != string(body) {
+			t.Fatalf("response body = %q, want %q", respBody, body)
+		}
+	}
+	if !gotResponse {
+		t.Fatal("expected at least one response record")
+	}
+}
+
+func TestArchiveNextURLRoundTripsAcrossReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	a, err := Open(dir, "resume-task")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	if url, err := a.NextURL(); err != nil || url != "" {
+		t.Fatalf("NextURL on a brand new task = (%q, %v), want (\"\", nil)", url, err)
+	}
+
+	if err := a.SetNextURL("https://example.com/page/2"); err != nil {
+		t.Fatalf("SetNextURL: %v", err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Reopening under the same dir/taskID (the resume path) must see the
+	// page a killed run was about to fetch next.
+	resumed, err := Open(dir, "resume-task")
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer resumed.Close()
+
+	url, err := resumed.NextURL()
+	if err != nil {
+		t.Fatalf("NextURL: %v", err)
+	}
+	if url != "https://example.com/page/2" {
+		t.Fatalf("NextURL after reopen = %q, want %q", url, "https://example.com/page/2")
+	}
+}
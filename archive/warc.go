@@ -0,0 +1,58 @@
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+// newWARCRequestRecord formats req as a minimal WARC 1.1 "request" record.
+// It intentionally implements just enough of the spec (mandatory headers
+// plus the raw HTTP request) for Replay to round-trip what Record wrote;
+// it is not a general-purpose WARC writer.
+func newWARCRequestRecord(req *http.Request) []byte {
+	raw, _ := httputil.DumpRequestOut(req, true)
+	return warcRecord("request", req.URL.String(), "application/http;msgtype=request", raw)
+}
+
+// newWARCResponseRecord formats resp as a minimal WARC 1.1 "response"
+// record, with body already read out of resp.Body by the caller.
+func newWARCResponseRecord(resp *http.Response, body []byte) []byte {
+	var buf bytes.Buffer
+	resp.Write(&buf)
+	// resp.Write re-serializes from resp.Body, which the caller has
+	// already drained; fall back to reassembling status line + headers
+	// + the body we were handed.
+	if buf.Len() == 0 || !bytes.Contains(buf.Bytes(), body) {
+		buf.Reset()
+		fmt.Fprintf(&buf, "HTTP/1.1 %s\r\n", resp.Status)
+		resp.Header.Write(&buf)
+		buf.WriteString("\r\n")
+		buf.Write(body)
+	}
+	return warcRecord("response", resp.Request.URL.String(), "application/http;msgtype=response", buf.Bytes())
+}
+
+// warcRecord formats one WARC 1.1 record. WARC-Record-ID and WARC-Date are
+// mandatory per the spec; WARC-Record-ID just needs to be a globally
+// unique URI, so a ksuid (already this module's ID scheme for tasks) under
+// an unregistered "ksuid" URN namespace does the job without pulling in a
+// UUID dependency.
+func warcRecord(recordType, targetURI, contentType string, content []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "WARC/1.1\r\n")
+	fmt.Fprintf(&buf, "WARC-Type: %s\r\n", recordType)
+	fmt.Fprintf(&buf, "WARC-Record-ID: <urn:ksuid:%s>\r\n", ksuid.New().String())
+	fmt.Fprintf(&buf, "WARC-Date: %s\r\n", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	fmt.Fprintf(&buf, "WARC-Target-URI: %s\r\n", targetURI)
+	fmt.Fprintf(&buf, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(&buf, "Content-Length: %d\r\n", len(content))
+	buf.WriteString("\r\n")
+	buf.Write(content)
+	buf.WriteString("\r\n\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,114 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Record is one parsed WARC record, as written by Record's WARC writer.
+type Record struct {
+	Type      string
+	TargetURI string
+	Content   []byte
+}
+
+// ReadRecords parses every record out of the WARC file at path, in the
+// order they were written. It understands only the subset of WARC 1.1 this
+// package itself writes.
+func ReadRecords(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("archive: opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var records []Record
+	r := bufio.NewReader(f)
+	for {
+		rec, err := readOneRecord(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("archive: parsing %s: %w", path, err)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+func readOneRecord(r *bufio.Reader) (Record, error) {
+	var rec Record
+
+	line, err := r.ReadString('\n')
+	if err == io.EOF {
+		return rec, io.EOF
+	}
+	if err != nil {
+		return rec, err
+	}
+	if strings.TrimSpace(line) != "WARC/1.1" {
+		return rec, fmt.Errorf("expected WARC/1.1 version line, got %q", line)
+	}
+
+	contentLength := -1
+	for {
+		line, err = r.ReadString('\n')
+		if err != nil {
+			return rec, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		val = strings.TrimSpace(val)
+		switch key {
+		case "WARC-Type":
+			rec.Type = val
+		case "WARC-Target-URI":
+			rec.TargetURI = val
+		case "Content-Length":
+			contentLength, err = strconv.Atoi(val)
+			if err != nil {
+				return rec, fmt.Errorf("bad Content-Length %q: %w", val, err)
+			}
+		}
+	}
+	if contentLength < 0 {
+		return rec, fmt.Errorf("record for %s missing Content-Length", rec.TargetURI)
+	}
+
+	content := make([]byte, contentLength)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return rec, err
+	}
+	rec.Content = content
+
+	// Consume the trailing blank-line record separator written by
+	// warcRecord ("\r\n\r\n" after the content).
+	if _, err := r.Discard(4); err != nil && err != io.EOF {
+		return rec, err
+	}
+
+	return rec, nil
+}
+
+// ResponseBody strips the HTTP status line and headers off a "response"
+// record's Content, returning just the page body that was served.
+func ResponseBody(rec Record) ([]byte, error) {
+	idx := bytes.Index(rec.Content, []byte("\r\n\r\n"))
+	if idx < 0 {
+		return nil, fmt.Errorf("archive: response record for %s has no header/body separator", rec.TargetURI)
+	}
+	return rec.Content[idx+4:], nil
+}
@@ -0,0 +1,243 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+var logger *log.Logger
+
+func init() {
+	logger = log.New(os.Stdout, "fetch: ", log.Lshortfile)
+}
+
+// Interaction describes a single scripted action to perform against the
+// rendered page before the DOM is captured. Interactions are executed in
+// order, which lets a Payload drive things a plain HTTP fetch can't, such as
+// clicking a "load more" button several times or typing into a search box.
+type Interaction struct {
+	// Type is one of "click", "scroll", "wait", "type" or "select".
+	Type string
+
+	// Selector is the CSS selector the interaction applies to. Unused for
+	// "wait".
+	Selector string
+
+	// Value holds the text to enter for "type", the option value for
+	// "select", or the scroll-by amount in pixels for "scroll".
+	Value string
+
+	// Delay is how long to pause after the interaction runs, to give the
+	// page time to react (e.g. an XHR triggered by a click).
+	Delay time.Duration
+
+	// Count repeats the interaction this many times. Zero means once.
+	// This is what makes "click .load-more" drive an infinite-scroll or
+	// paginated listing without a separate Interaction per click.
+	Count int
+}
+
+// DynamicFetcherRequest is the request type consumed by DynamicFetcher.Fetch.
+type DynamicFetcherRequest struct {
+	URL string
+
+	// Interactions are replayed in order before the page is serialized.
+	Interactions []Interaction
+
+	// Timeout bounds the whole fetch, including all interactions. Zero
+	// means DefaultTimeout is used.
+	Timeout time.Duration
+
+	// Debug, when true, dumps the outerHTML and a screenshot after every
+	// interaction step to DebugDir so selectors can be diagnosed.
+	Debug bool
+
+	// DebugDir is where debug artifacts are written. Defaults to the OS
+	// temp dir when empty.
+	DebugDir string
+}
+
+// DefaultTimeout bounds a DynamicFetcher.Fetch call when the request does
+// not specify one.
+const DefaultTimeout = 30 * time.Second
+
+// DynamicFetcher renders pages with a headless, CDP-controlled browser and
+// replays a scripted sequence of interactions before extracting the DOM.
+// It replaces the Scraper's plain HTTP fetch for JS-heavy pages that need
+// clicks, scrolling or form input before the content of interest appears.
+type DynamicFetcher struct {
+	// ExecAllocatorOptions are appended to chromedp's default allocator
+	// options. Left nil to use the defaults (headless, sandboxed).
+	ExecAllocatorOptions []chromedp.ExecAllocatorOption
+
+	// Interactions, Timeout, Debug and DebugDir are the defaults applied
+	// to every Fetch call that passes a bare URL instead of a full
+	// DynamicFetcherRequest - this is how a Payload's DynamicFetcher
+	// config reaches the fetcher that NewScraper builds from it.
+	Interactions []Interaction
+	Timeout      time.Duration
+	Debug        bool
+	DebugDir     string
+}
+
+// NewDynamicFetcher returns a DynamicFetcher that replays cfg's
+// Interactions, and applies its Timeout/Debug/DebugDir, on every page it
+// fetches.
+func NewDynamicFetcher(cfg DynamicFetcherRequest) *DynamicFetcher {
+	return &DynamicFetcher{
+		Interactions: cfg.Interactions,
+		Timeout:      cfg.Timeout,
+		Debug:        cfg.Debug,
+		DebugDir:     cfg.DebugDir,
+	}
+}
+
+// Prepare implements the fetch.Fetcher interface. DynamicFetcher needs no
+// setup beyond what chromedp does lazily on first Fetch.
+func (d *DynamicFetcher) Prepare() error {
+	return nil
+}
+
+// Fetch renders the requested page, replays its Interactions in order and
+// returns the final document as an HTML string read from
+// document.documentElement.outerHTML. request is either a plain URL
+// string, which is merged with d's configured Interactions/Timeout/Debug,
+// or a fully-populated DynamicFetcherRequest that overrides them outright.
+// The browser context is cancelled as soon as the timeout elapses, so a
+// hung page can't wedge the caller.
+func (d *DynamicFetcher) Fetch(request interface{}) (interface{}, error) {
+	req, err := d.requestFor(request)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := req.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	allocOpts := append(chromedp.DefaultExecAllocatorOptions[:], d.ExecAllocatorOptions...)
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), allocOpts...)
+	defer allocCancel()
+
+	ctx, cancel := chromedp.NewContext(allocCtx)
+	defer cancel()
+
+	ctx, timeoutCancel := context.WithTimeout(ctx, timeout)
+	defer timeoutCancel()
+
+	tasks := chromedp.Tasks{chromedp.Navigate(req.URL)}
+
+	for i, in := range req.Interactions {
+		tasks = append(tasks, interactionTasks(in)...)
+		if req.Debug {
+			step := i
+			action := in
+			tasks = append(tasks, chromedp.ActionFunc(func(ctx context.Context) error {
+				return dumpDebugStep(ctx, req.DebugDir, step, action)
+			}))
+		}
+	}
+
+	var html string
+	tasks = append(tasks, chromedp.OuterHTML("html", &html))
+
+	if err := chromedp.Run(ctx, tasks); err != nil {
+		return nil, fmt.Errorf("fetch: dynamic fetch of %s failed: %w", req.URL, err)
+	}
+
+	return html, nil
+}
+
+// requestFor normalizes whatever Fetch was called with into a
+// DynamicFetcherRequest: a bare URL string picks up d's configured
+// Interactions/Timeout/Debug/DebugDir, while a DynamicFetcherRequest is
+// used as given.
+func (d *DynamicFetcher) requestFor(request interface{}) (DynamicFetcherRequest, error) {
+	switch v := request.(type) {
+	case string:
+		return DynamicFetcherRequest{
+			URL:          v,
+			Interactions: d.Interactions,
+			Timeout:      d.Timeout,
+			Debug:        d.Debug,
+			DebugDir:     d.DebugDir,
+		}, nil
+	case DynamicFetcherRequest:
+		return v, nil
+	default:
+		return DynamicFetcherRequest{}, fmt.Errorf("fetch: DynamicFetcher.Fetch expects a URL string or DynamicFetcherRequest, got %T", request)
+	}
+}
+
+// interactionTasks expands a single Interaction into the chromedp actions
+// that implement it, repeating Count times (at least once).
+func interactionTasks(in Interaction) chromedp.Tasks {
+	count := in.Count
+	if count <= 0 {
+		count = 1
+	}
+
+	var tasks chromedp.Tasks
+	for i := 0; i < count; i++ {
+		switch in.Type {
+		case "click":
+			tasks = append(tasks, chromedp.Click(in.Selector, chromedp.ByQuery))
+		case "scroll":
+			if in.Selector == "" {
+				amount := in.Value
+				if amount == "" {
+					amount = "window.innerHeight"
+				}
+				tasks = append(tasks, chromedp.Evaluate(fmt.Sprintf("window.scrollBy(0,%s)", amount), nil))
+			} else {
+				tasks = append(tasks, chromedp.ScrollIntoView(in.Selector, chromedp.ByQuery))
+			}
+		case "type":
+			tasks = append(tasks, chromedp.SendKeys(in.Selector, in.Value, chromedp.ByQuery))
+		case "select":
+			tasks = append(tasks, chromedp.SetValue(in.Selector, in.Value, chromedp.ByQuery))
+		case "wait":
+			// Selector-less wait; Delay below does the work.
+		default:
+			logger.Printf("unknown interaction type %q, skipping", in.Type)
+		}
+		if in.Delay > 0 {
+			tasks = append(tasks, chromedp.Sleep(in.Delay))
+		}
+	}
+	return tasks
+}
+
+// dumpDebugStep writes the current outerHTML and a screenshot to DebugDir,
+// named after the interaction step, for troubleshooting selectors that
+// don't match what the caller expected.
+func dumpDebugStep(ctx context.Context, dir string, step int, in Interaction) error {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	var html string
+	var shot []byte
+	if err := chromedp.Run(ctx,
+		chromedp.OuterHTML("html", &html),
+		chromedp.FullScreenshot(&shot, 90),
+	); err != nil {
+		return err
+	}
+
+	base := fmt.Sprintf("%s/step-%02d-%s", dir, step, in.Type)
+	if err := os.WriteFile(base+".html", []byte(html), 0o644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(base+".png", shot, 0o644); err != nil {
+		return err
+	}
+	logger.Printf("debug: wrote %s.{html,png}", base)
+	return nil
+}
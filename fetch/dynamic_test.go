@@ -0,0 +1,20 @@
+package fetch
+
+import "testing"
+
+func TestInteractionTasksScrollWithoutSelectorStillProducesATask(t *testing.T) {
+	// A selector-less, pixel-based scroll (the infinite-scroll use case)
+	// must still expand to a chromedp action; before this was fixed it
+	// silently dropped to a no-op ScrollIntoView("").
+	tasks := interactionTasks(Interaction{Type: "scroll", Value: "2000", Count: 3})
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 scroll tasks for Count=3, got %d", len(tasks))
+	}
+}
+
+func TestInteractionTasksScrollWithSelectorUsesScrollIntoView(t *testing.T) {
+	tasks := interactionTasks(Interaction{Type: "scroll", Selector: "#footer"})
+	if len(tasks) != 1 {
+		t.Fatalf("expected 1 scroll task, got %d", len(tasks))
+	}
+}
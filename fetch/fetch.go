@@ -0,0 +1,14 @@
+// Package fetch retrieves the raw page content a Scraper divides into
+// blocks and extracts from.
+package fetch
+
+// Fetcher retrieves a single page. Prepare is called once before a
+// Fetcher's first use to do any one-time setup (e.g. launching a browser
+// process); Fetch performs the actual request and returns the response
+// body in whatever form is appropriate for the implementation (an HTML
+// string for DynamicFetcher; scrape.Scraper.Run also accepts an io.Reader)
+// for the caller to hand to goquery.
+type Fetcher interface {
+	Prepare() error
+	Fetch(request interface{}) (interface{}, error)
+}
@@ -0,0 +1,300 @@
+package scrape
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// AutoconfigOpts tunes Autoconfig's DOM-clustering heuristics.
+type AutoconfigOpts struct {
+	// MinBlockCount is the minimum number of repeating elements required
+	// for a tag-path fingerprint to be considered a candidate block.
+	// Defaults to 3.
+	MinBlockCount int
+
+	// Client fetches the sample page. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Autoconfig downloads url and infers a plausible Payload from it: it
+// clusters elements that share the same tag-path fingerprint (the
+// concatenation of ancestor "tag[nth-of-type]" segments up to <body>),
+// scores each cluster by count × depth × (1 + text-density), and treats the
+// highest-scoring cluster as the repeating block. Within that block, one
+// Field is emitted per leaf whose text/href/src value is present in most
+// of the block's instances and varies across them; leaves whose value is
+// constant across every instance are dropped as chrome rather than data.
+//
+// The returned Payload is plain JSON-serializable data, so callers can
+// hand-edit it before submitting it to the existing scraping endpoints,
+// turning this auto-config pass into a first draft rather than a final
+// answer.
+func Autoconfig(url string, opts AutoconfigOpts) (*Payload, error) {
+	if opts.MinBlockCount <= 0 {
+		opts.MinBlockCount = 3
+	}
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("scrape: autoconfig fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("scrape: autoconfig parsing %s: %w", url, err)
+	}
+
+	best := bestFingerprintGroup(doc.Selection, opts.MinBlockCount)
+	if best == nil {
+		return nil, fmt.Errorf("scrape: autoconfig found no repeating block on %s", url)
+	}
+
+	fields := fieldsFromGroup(best)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("scrape: autoconfig found a block on %s but no varying fields within it", url)
+	}
+
+	paginateResults := true
+	randomizeFetchDelay := false
+	return &Payload{
+		Fields:              fields,
+		PaginateResults:     &paginateResults,
+		RandomizeFetchDelay: &randomizeFetchDelay,
+	}, nil
+}
+
+// fingerprintGroup is every element sharing one tag-path fingerprint.
+type fingerprintGroup struct {
+	fingerprint string
+	depth       int
+	nodes       []*goquery.Selection
+}
+
+// score implements count × depth × (1 + text-density), where text-density
+// is the average trimmed text length per node (the 1 keeps text-less
+// groups from scoring zero): a deep, frequently
+// repeated, text-bearing fingerprint is almost always the listing we want,
+// while shallow or sparse fingerprints (nav chrome, single-instance
+// headers) score low.
+func (g *fingerprintGroup) score() float64 {
+	var totalText int
+	for _, n := range g.nodes {
+		totalText += len(strings.TrimSpace(n.Text()))
+	}
+	density := float64(totalText) / float64(len(g.nodes))
+	return float64(len(g.nodes)) * float64(g.depth) * (1 + density)
+}
+
+// bestFingerprintGroup walks every element under root, groups them by
+// fingerprint, and returns the highest-scoring group with at least
+// minCount members, or nil if none qualifies.
+func bestFingerprintGroup(root *goquery.Selection, minCount int) *fingerprintGroup {
+	groups := map[string]*fingerprintGroup{}
+
+	root.Find("*").Each(func(_ int, s *goquery.Selection) {
+		if s.Nodes[0].Type != html.ElementNode {
+			return
+		}
+		switch s.Nodes[0].Data {
+		case "script", "style", "noscript", "head":
+			return
+		}
+
+		fp, depth := fingerprint(s.Nodes[0])
+		g, ok := groups[fp]
+		if !ok {
+			g = &fingerprintGroup{fingerprint: fp, depth: depth}
+			groups[fp] = g
+		}
+		g.nodes = append(g.nodes, s)
+	})
+
+	var best *fingerprintGroup
+	var bestScore float64
+	for _, g := range groups {
+		if len(g.nodes) < minCount {
+			continue
+		}
+		if score := g.score(); best == nil || score > bestScore {
+			best, bestScore = g, score
+		}
+	}
+	return best
+}
+
+// fingerprint builds node's tag-path fingerprint: "tag[nth-of-type]"
+// segments for node's ancestors up to (and excluding) <body>, followed by
+// node's own bare tag name. It returns the fingerprint and its depth
+// (segment count), since a deeper repeating structure is a stronger block
+// signal than a shallow one.
+//
+// node's own nth-of-type is deliberately left out of the fingerprint:
+// siblings repeating under the same parent (e.g. five <li> in one <ul>)
+// necessarily each have a different nth-of-type, so including it would put
+// every instance of the repeating block in its own singleton group and
+// bestFingerprintGroup would never find anything to cluster. Ancestor
+// indices are kept so that, say, a sidebar's <li>s and a listing's <li>s
+// still land in different groups.
+func fingerprint(node *html.Node) (string, int) {
+	var segments []string
+	first := true
+	for n := node; n != nil && n.Data != "body"; n = n.Parent {
+		if n.Type != html.ElementNode {
+			continue
+		}
+		seg := n.Data
+		if !first {
+			seg = fmt.Sprintf("%s[%d]", n.Data, nthOfType(n))
+		}
+		first = false
+		segments = append([]string{seg}, segments...)
+	}
+	return strings.Join(segments, ">"), len(segments)
+}
+
+// nthOfType returns n's 1-based position among its parent's children that
+// share its tag name.
+func nthOfType(n *html.Node) int {
+	if n.Parent == nil {
+		return 1
+	}
+	idx := 1
+	for sib := n.Parent.FirstChild; sib != nil; sib = sib.NextSibling {
+		if sib == n {
+			return idx
+		}
+		if sib.Type == html.ElementNode && sib.Data == n.Data {
+			idx++
+		}
+	}
+	return idx
+}
+
+// leafValue is one candidate value extracted from a single leaf within one
+// instance of the winning block.
+type leafValue struct {
+	extractorType string // "text", "link", "image" or "attr"
+	attr          string // set when extractorType == "attr"
+	value         string
+}
+
+// fieldsFromGroup inspects every leaf descendant (and the root itself) of
+// each instance in g, keeping only those present in a majority of
+// instances and non-constant across them, and emits one Field per such
+// leaf with an inferred extractor type.
+func fieldsFromGroup(g *fingerprintGroup) []Field {
+	type leafStats struct {
+		selector      string
+		extractorType string
+		attr          string
+		values        map[string]struct{}
+		present       int
+	}
+	stats := map[string]*leafStats{}
+
+	for _, instance := range g.nodes {
+		for selector, lv := range candidateLeaves(instance) {
+			key := selector + "|" + lv.extractorType + "|" + lv.attr
+			st, ok := stats[key]
+			if !ok {
+				st = &leafStats{
+					selector:      selector,
+					extractorType: lv.extractorType,
+					attr:          lv.attr,
+					values:        map[string]struct{}{},
+				}
+				stats[key] = st
+			}
+			st.present++
+			st.values[lv.value] = struct{}{}
+		}
+	}
+
+	var fields []Field
+	fieldNum := 0
+	for _, st := range stats {
+		majority := st.present*2 > len(g.nodes)
+		varying := len(st.values) > 1
+		if !majority || !varying {
+			continue
+		}
+		fieldNum++
+		params := map[string]interface{}{}
+		if st.attr != "" {
+			params["attr"] = st.attr
+		}
+		fields = append(fields, Field{
+			Name:     fmt.Sprintf("field_%d", fieldNum),
+			Selector: st.selector,
+			Extractor: FieldExtractor{
+				Type:   st.extractorType,
+				Params: params,
+			},
+		})
+	}
+	return fields
+}
+
+// candidateLeaves returns every leaf value found directly on instance and
+// its descendants, keyed by a selector relative to instance: "." for the
+// instance itself, otherwise a "tag:nth-of-type(n)" chain from instance
+// down to the leaf. The nth-of-type chain is required, not just the bare
+// tag name: a block commonly has more than one descendant of the same tag
+// (a thumbnail <img> plus other <img>s, a title <a> plus a "read more"
+// <a>), and a bare tag name would let them overwrite each other in the map
+// and produce a Field.Selector that's ambiguous at scrape time.
+func candidateLeaves(instance *goquery.Selection) map[string]leafValue {
+	leaves := map[string]leafValue{}
+	root := instance.Nodes[0]
+
+	addLeaf := func(selector string, sel *goquery.Selection) {
+		if href, ok := sel.Attr("href"); ok && strings.TrimSpace(href) != "" {
+			leaves[selector] = leafValue{extractorType: "link", value: href}
+			return
+		}
+		if src, ok := sel.Attr("src"); ok && strings.TrimSpace(src) != "" {
+			leaves[selector] = leafValue{extractorType: "image", value: src}
+			return
+		}
+		if text := strings.TrimSpace(sel.Text()); text != "" {
+			leaves[selector] = leafValue{extractorType: "text", value: text}
+		}
+	}
+
+	addLeaf(".", instance)
+	instance.Find("*").Each(func(_ int, sel *goquery.Selection) {
+		if sel.Children().Length() > 0 {
+			// Not a leaf; its own descendants are considered instead.
+			return
+		}
+		addLeaf(relativeSelector(root, sel.Nodes[0]), sel)
+	})
+
+	return leaves
+}
+
+// relativeSelector builds a CSS selector for leaf, scoped to root (leaf's
+// own instance), as a "tag:nth-of-type(n)" chain joined by child
+// combinators. It returns "." when leaf is root itself.
+func relativeSelector(root, leaf *html.Node) string {
+	if leaf == root {
+		return "."
+	}
+	var segments []string
+	for n := leaf; n != nil && n != root; n = n.Parent {
+		if n.Type != html.ElementNode {
+			continue
+		}
+		segments = append([]string{fmt.Sprintf("%s:nth-of-type(%d)", n.Data, nthOfType(n))}, segments...)
+	}
+	return strings.Join(segments, " > ")
+}
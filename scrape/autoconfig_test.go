@@ -0,0 +1,66 @@
+package scrape
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const listingHTML = `
+<html><body>
+<ul class="listing">
+  <li><a href="/items/1">First widget</a></li>
+  <li><a href="/items/2">Second widget</a></li>
+  <li><a href="/items/3">Third widget</a></li>
+  <li><a href="/items/4">Fourth widget</a></li>
+</ul>
+</body></html>`
+
+func TestBestFingerprintGroupFindsRepeatingSiblings(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(listingHTML))
+	if err != nil {
+		t.Fatalf("parsing fixture: %v", err)
+	}
+
+	g := bestFingerprintGroup(doc.Selection, 3)
+	if g == nil {
+		t.Fatal("expected a repeating block to be found, got nil")
+	}
+	if len(g.nodes) != 4 {
+		t.Fatalf("expected all 4 <li> siblings in one group, got %d", len(g.nodes))
+	}
+}
+
+func TestFieldsFromGroupEmitsVaryingFields(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(listingHTML))
+	if err != nil {
+		t.Fatalf("parsing fixture: %v", err)
+	}
+
+	g := bestFingerprintGroup(doc.Selection, 3)
+	if g == nil {
+		t.Fatal("expected a repeating block to be found, got nil")
+	}
+
+	fields := fieldsFromGroup(g)
+	if len(fields) == 0 {
+		t.Fatal("expected at least one varying field, got none")
+	}
+
+	var sawLink, sawText bool
+	for _, f := range fields {
+		switch f.Extractor.Type {
+		case "link":
+			sawLink = true
+		case "text":
+			sawText = true
+		}
+	}
+	if !sawLink {
+		t.Error("expected a link field for the <a href>")
+	}
+	if !sawText {
+		t.Error("expected a text field for the <a> text")
+	}
+}
@@ -0,0 +1,125 @@
+package scrape
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ErrMaxDepthExceeded is returned by FollowDetails when following a
+// LinkPrimary link would recurse deeper than the caller's maxDepth,
+// protecting against runaway crawls caused by a cyclic or mistagged config.
+var ErrMaxDepthExceeded = errors.New("scrape: max primary-link depth exceeded")
+
+// RelatedURLs deduplicates LinkRelated URLs across an entire task, so the
+// same image or stylesheet linked from many blocks is only archived once.
+// The zero value is ready to use.
+type RelatedURLs struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// SeenOrMark reports whether url has already been recorded and, if not,
+// records it. It's safe for concurrent use across blocks and pages.
+func (r *RelatedURLs) SeenOrMark(url string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seen == nil {
+		r.seen = make(map[string]struct{})
+	}
+	if _, ok := r.seen[url]; ok {
+		return true
+	}
+	r.seen[url] = struct{}{}
+	return false
+}
+
+// FollowDetails walks part.Details for the detail link found in block
+// (extracted via part.Selector/Extractor), nesting the detail scrape's
+// results under part.Name in blockResults. A LinkRelated part's URL is
+// recorded in related and, the first time it is seen, fetched once with
+// fetchFn; the fetched document is discarded, not scraped. depth is the
+// number of primary links already followed to reach block; FollowDetails
+// refuses to recurse past maxDepth.
+//
+// fetchFn performs the actual HTTP/headless fetch for a detail or related
+// URL and returns a goquery document for it; it's injected so this function
+// stays agnostic of which Fetcher the caller is using.
+func FollowDetails(part Part, block *goquery.Selection, depth, maxDepth int, related *RelatedURLs, fetchFn func(url string) (*goquery.Document, error)) (interface{}, error) {
+	if part.Details == nil {
+		return nil, nil
+	}
+
+	sel := block
+	if part.Selector != "." {
+		sel = sel.Find(part.Selector)
+	}
+
+	href, err := part.Extractor.Extract(sel)
+	if err != nil || href == nil {
+		return nil, err
+	}
+	url, ok := href.(string)
+	if !ok || url == "" {
+		return nil, nil
+	}
+
+	if part.LinkTag == LinkRelated {
+		if !related.SeenOrMark(url) {
+			if _, err := fetchFn(url); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	if depth >= maxDepth {
+		return nil, ErrMaxDepthExceeded
+	}
+
+	doc, err := fetchFn(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []map[string]interface{}
+	for _, detailBlock := range part.Details.DividePage(doc.Selection) {
+		blockResults := map[string]interface{}{}
+		for _, p := range part.Details.Parts {
+			detailSel := detailBlock
+			if p.Selector != "." {
+				detailSel = detailSel.Find(p.Selector)
+			}
+			val, err := p.Extractor.Extract(detailSel)
+			if err != nil {
+				return nil, err
+			}
+			if val == nil {
+				continue
+			}
+			blockResults[p.Name] = val
+
+			if p.Details != nil {
+				nested, err := FollowDetails(p, detailBlock, depth+1, maxDepth, related, fetchFn)
+				if err != nil {
+					return nil, err
+				}
+				if nested != nil {
+					blockResults[p.Name] = nested
+				}
+			}
+		}
+		if len(blockResults) > 0 {
+			results = append(results, blockResults)
+		}
+	}
+
+	// A single detail page is the common case (one "read more" link per
+	// block); flatten it instead of forcing callers to unwrap a
+	// one-element slice.
+	if len(results) == 1 {
+		return results[0], nil
+	}
+	return results, nil
+}
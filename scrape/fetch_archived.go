@@ -0,0 +1,58 @@
+package scrape
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ErrAlreadyFetched is returned by Task.FetchArchived when url is already
+// recorded in the task's archive seen-set, so a resumed scrape can skip it
+// instead of re-fetching.
+var ErrAlreadyFetched = errors.New("scrape: URL already fetched in this task's archive")
+
+// FetchArchived fetches url with client, skipping it with ErrAlreadyFetched
+// if the task's Archive has already recorded it (the resume path), and
+// otherwise recording the request/response pair to the archive's WARC file
+// before returning the parsed document. When the task has no Archive
+// configured, it behaves like a plain fetch.
+func (t *Task) FetchArchived(client *http.Client, url string) (*goquery.Document, error) {
+	if t.Session.Archive != nil {
+		seen, err := t.Session.Archive.Seen(url)
+		if err != nil {
+			return nil, fmt.Errorf("scrape: checking archive seen-set for %s: %w", url, err)
+		}
+		if seen {
+			return nil, ErrAlreadyFetched
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("scrape: building request for %s: %w", url, err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("scrape: fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("scrape: reading body of %s: %w", url, err)
+	}
+
+	if t.Session.Archive != nil {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err := t.Session.Archive.Record(req, resp, body); err != nil {
+			return nil, fmt.Errorf("scrape: archiving %s: %w", url, err)
+		}
+	}
+
+	return goquery.NewDocumentFromReader(bytes.NewReader(body))
+}
@@ -0,0 +1,36 @@
+package scrape
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/slotix/dataflowkit/archive"
+)
+
+func TestTaskFetchArchivedRecordsAndSkipsOnResume(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>ok</body></html>"))
+	}))
+	defer srv.Close()
+
+	a, err := archive.Open(t.TempDir(), "resume-test")
+	if err != nil {
+		t.Fatalf("archive.Open: %v", err)
+	}
+	defer a.Close()
+
+	task := &Task{Session: Session{Archive: a}}
+
+	doc, err := task.FetchArchived(srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatalf("FetchArchived: %v", err)
+	}
+	if got := doc.Find("body").Text(); got != "ok" {
+		t.Fatalf("body text = %q, want %q", got, "ok")
+	}
+
+	if _, err := task.FetchArchived(srv.Client(), srv.URL); err != ErrAlreadyFetched {
+		t.Fatalf("second FetchArchived of the same URL: got err %v, want ErrAlreadyFetched", err)
+	}
+}
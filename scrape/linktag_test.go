@@ -0,0 +1,16 @@
+package scrape
+
+import "testing"
+
+func TestLinkTagFromString(t *testing.T) {
+	cases := map[string]LinkTag{
+		"":        LinkPrimary,
+		"primary": LinkPrimary,
+		"related": LinkRelated,
+	}
+	for in, want := range cases {
+		if got := linkTagFromString(in); got != want {
+			t.Errorf("linkTagFromString(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package scrape
+
+import "time"
+
+// ScrapeOptions holds the settings that govern how a Scraper behaves while
+// a scrape is in progress, as opposed to what it extracts (that's Parts).
+type ScrapeOptions struct {
+	// MaxPages caps how many pages are fetched; zero means no limit.
+	MaxPages int
+
+	// Format is the output format requested for the scrape.
+	Format string
+
+	// PaginateResults controls whether results are grouped per page or
+	// flattened across all pages.
+	PaginateResults bool
+
+	// FetchDelay is how long to wait between fetching successive pages.
+	FetchDelay time.Duration
+
+	// RandomizeFetchDelay jitters FetchDelay to avoid a perfectly
+	// regular request cadence.
+	RandomizeFetchDelay bool
+
+	// RetryTimes is how many times to retry a failed fetch before giving
+	// up on a page.
+	RetryTimes int
+
+	// ObeyRobots, when true, makes the Scheduler consult each host's
+	// robots.txt before fetching and refuse disallowed URLs.
+	ObeyRobots bool
+
+	// MaxConcurrentPerHost caps how many fetches may be in flight for a
+	// single host at once, across every task running in the process.
+	// Zero means unlimited.
+	MaxConcurrentPerHost int
+
+	// HostQPS caps the average number of requests per second issued to
+	// a single host, shared across every task running in the process.
+	// Zero means unlimited.
+	HostQPS float64
+}
@@ -0,0 +1,109 @@
+package scrape
+
+import (
+	"io"
+	"time"
+
+	"github.com/slotix/dataflowkit/fetch"
+)
+
+// Payload is the wire format a caller submits to request a scrape: a list
+// of Fields to extract plus the options that govern how the resulting
+// Scraper behaves.
+type Payload struct {
+	// Fields describes what to extract from every block on every page.
+	Fields []Field
+
+	// Paginator describes how to find the next page. A nil Paginator
+	// means the scrape is limited to the initial URL.
+	Paginator *PayloadPaginator
+
+	// Format is the output format requested for the scrape: "json" (the
+	// default) keeps results in memory on Task.Results, "csv" and
+	// "jsonlines" stream blocks to Output as they're extracted via
+	// CSVSink/JSONLinesSink.
+	Format string
+
+	// Output is where NewScraper's Format-selected streaming sink
+	// (CSVSink, JSONLinesSink) writes. Required for "csv"/"jsonlines";
+	// ignored for "json".
+	Output io.Writer
+
+	// PaginateResults controls whether results are grouped per page
+	// (true) or flattened across all pages (false).
+	PaginateResults *bool
+
+	// FetchDelay is how long to wait between fetching successive pages.
+	FetchDelay time.Duration
+
+	// RandomizeFetchDelay jitters FetchDelay to avoid a perfectly
+	// regular request cadence.
+	RandomizeFetchDelay *bool
+
+	// RetryTimes is how many times to retry a failed fetch before giving
+	// up on a page.
+	RetryTimes int
+
+	// DynamicFetcher, when set, renders pages with a headless browser
+	// and replays its Interactions before the DOM is captured, instead
+	// of Run's plain HTTP fetch.
+	DynamicFetcher *fetch.DynamicFetcherRequest
+
+	// ResumeTaskID reuses a previous task's ID instead of generating a
+	// new one, so NewTask appends to that task's existing archive
+	// rather than starting a fresh one.
+	ResumeTaskID string
+
+	// ArchiveDir, when non-empty, enables archival: every fetched page
+	// is written to a WARC file under this directory and its URL is
+	// recorded in a persistent seen-set keyed by task ID.
+	ArchiveDir string
+}
+
+// Field describes one piece of data to extract from every block.
+type Field struct {
+	// Name identifies this field in the results map.
+	Name string
+
+	// Selector is the sub-selector within a block that this field reads
+	// from.
+	Selector string
+
+	// Extractor says how to turn whatever Selector matches into a value.
+	Extractor FieldExtractor
+
+	// Details, when set, is run as a nested scrape against the page
+	// linked to by this Field's extracted href, and its results are
+	// merged back into the parent block's result map under this
+	// Field's Name. Meaningful for "link" (applied to the "_link"
+	// part) and "attr" (typically Attr: "href") extractors; ignored
+	// for "image" fields. This is how a Payload asks for detail-page
+	// following without the caller having to stitch two separate
+	// scrape configs together.
+	Details *Payload
+
+	// LinkTag is "primary" (the default) or "related", and has the same
+	// meaning as Part.LinkTag: a primary link is scraped recursively
+	// with Details and merged into the result, a related link is only
+	// fetched once and not scraped. Ignored when Details is nil.
+	LinkTag string
+}
+
+// FieldExtractor names an extractor type ("text", "link", "image",
+// "attr", "const", "count", "html", "outerHtml" or "regex") and carries
+// its type-specific parameters, which are applied to the underlying
+// extract.Extractor via FillStruct.
+type FieldExtractor struct {
+	Type   string
+	Params interface{}
+}
+
+// PayloadPaginator configures how NewScraper builds a paginate.Paginator.
+type PayloadPaginator struct {
+	// Selector matches the "next page" link.
+	Selector string
+	// Attribute is the link attribute to follow, typically "href".
+	Attribute string
+	// MaxPages caps how many pages are fetched; zero means no limit.
+	MaxPages int
+}
@@ -0,0 +1,185 @@
+package scrape
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultMaxDetailDepth caps how many primary Details links Run follows
+// recursively per block.
+const DefaultMaxDetailDepth = 5
+
+// Run performs the scrape described by s, starting at startURL, and writes
+// each block to s.Sink as soon as it's extracted rather than holding the
+// whole result set in memory. If s.Fetcher is set, it's used to retrieve
+// each page (driving a headless-browser render for a DynamicFetcher);
+// otherwise pages are fetched with client, going through t's Archive when
+// configured so the page is recorded and a resumed task can skip it.
+// scheduler, if non-nil, gates every listing-page fetch behind its
+// robots.txt, concurrency and rate-limit rules. Details and related links
+// are fetched with a plain HTTP GET (httpGetDoc) that goes through neither
+// scheduler nor t's Archive.
+func (s *Scraper) Run(t *Task, client *http.Client, scheduler *Scheduler, startURL string) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if s.Sink == nil {
+		s.Sink = &t.Results
+	}
+	if s.Fetcher != nil {
+		if err := s.Fetcher.Prepare(); err != nil {
+			return fmt.Errorf("scrape: preparing fetcher: %w", err)
+		}
+	}
+
+	if err := s.Sink.Open(); err != nil {
+		return fmt.Errorf("scrape: opening sink: %w", err)
+	}
+	defer s.Sink.Close()
+
+	if t.Results.Visited == nil {
+		t.Results.Visited = map[string]error{}
+	}
+
+	// A resumed task (t.Session.Archive already has a seen-set on disk)
+	// picks up from the last page Run got to, not startURL - otherwise
+	// resume would always re-hit the listing's first page, find it
+	// already seen, and stop with zero results without ever reaching
+	// page 2+.
+	url := startURL
+	if t.Session.Archive != nil {
+		resumeURL, err := t.Session.Archive.NextURL()
+		if err != nil {
+			return fmt.Errorf("scrape: reading resume state: %w", err)
+		}
+		if resumeURL != "" {
+			url = resumeURL
+		}
+	}
+
+	related := &RelatedURLs{}
+	pageIdx := 0
+
+	for url != "" && (s.Opts.MaxPages == 0 || pageIdx < s.Opts.MaxPages) {
+		doc, err := s.fetchPage(t, client, scheduler, url)
+		if err != nil {
+			t.Results.Visited[url] = err
+			if errors.Is(err, ErrDisallowedByRobots) || errors.Is(err, ErrAlreadyFetched) {
+				// Not an error for the scrape as a whole: without this
+				// page there is no next-page link to follow, so the
+				// crawl stops here and keeps what it has written.
+				break
+			}
+			return err
+		}
+		t.Results.Visited[url] = nil
+
+		if err := s.extractPage(doc, pageIdx, related); err != nil {
+			return err
+		}
+
+		next, err := s.Paginator.NextPage(url, doc.Selection)
+		if err != nil {
+			return fmt.Errorf("scrape: finding next page after %s: %w", url, err)
+		}
+		if t.Session.Archive != nil {
+			if err := t.Session.Archive.SetNextURL(next); err != nil {
+				return fmt.Errorf("scrape: persisting resume state: %w", err)
+			}
+		}
+		url = next
+		pageIdx++
+	}
+
+	return nil
+}
+
+// fetchPage retrieves url, going through scheduler (if set) and then
+// either s.Fetcher or t's archive-backed plain HTTP fetch.
+func (s *Scraper) fetchPage(t *Task, client *http.Client, scheduler *Scheduler, url string) (*goquery.Document, error) {
+	if scheduler != nil {
+		release, err := scheduler.Allow(url)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	if s.Fetcher != nil {
+		raw, err := s.Fetcher.Fetch(url)
+		if err != nil {
+			return nil, fmt.Errorf("scrape: fetching %s: %w", url, err)
+		}
+		switch v := raw.(type) {
+		case string:
+			return goquery.NewDocumentFromReader(strings.NewReader(v))
+		case io.Reader:
+			return goquery.NewDocumentFromReader(v)
+		default:
+			return nil, fmt.Errorf("scrape: Fetcher returned unsupported type %T for %s", raw, url)
+		}
+	}
+
+	return t.FetchArchived(client, url)
+}
+
+// extractPage divides doc into blocks, extracts every Part from each
+// (following Details links via FollowDetails), and writes non-empty
+// results to s.Sink under pageIdx.
+func (s *Scraper) extractPage(doc *goquery.Document, pageIdx int, related *RelatedURLs) error {
+	for _, block := range s.DividePage(doc.Selection) {
+		blockResults := map[string]interface{}{}
+
+		for _, part := range s.Parts {
+			sel := block
+			if part.Selector != "." {
+				sel = sel.Find(part.Selector)
+			}
+
+			val, err := part.Extractor.Extract(sel)
+			if err != nil {
+				return fmt.Errorf("scrape: extracting %s: %w", part.Name, err)
+			}
+
+			if part.Details != nil {
+				nested, err := FollowDetails(part, block, 0, DefaultMaxDetailDepth, related, httpGetDoc)
+				if err != nil {
+					return fmt.Errorf("scrape: following details for %s: %w", part.Name, err)
+				}
+				if nested != nil {
+					blockResults[part.Name] = nested
+				}
+				continue
+			}
+
+			if val == nil {
+				continue
+			}
+			blockResults[part.Name] = val
+		}
+
+		if len(blockResults) == 0 {
+			continue
+		}
+		if err := s.Sink.WriteBlock(pageIdx, blockResults); err != nil {
+			return fmt.Errorf("scrape: writing block: %w", err)
+		}
+	}
+	return nil
+}
+
+// httpGetDoc is the default fetchFn FollowDetails uses to retrieve a
+// detail or related-asset URL with a plain HTTP GET.
+func httpGetDoc(url string) (*goquery.Document, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("scrape: fetching detail %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	return goquery.NewDocumentFromReader(resp.Body)
+}
@@ -0,0 +1,108 @@
+package scrape
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/slotix/dataflowkit/extract"
+)
+
+// collectSink is a minimal ResultSink that just remembers every block
+// it's given, for asserting on in tests.
+type collectSink struct {
+	blocks []map[string]interface{}
+}
+
+func (c *collectSink) Open() error { return nil }
+func (c *collectSink) WriteBlock(pageIdx int, block map[string]interface{}) error {
+	c.blocks = append(c.blocks, block)
+	return nil
+}
+func (c *collectSink) Close() error { return nil }
+
+func TestExtractPageWritesEachBlockToSink(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`
+<html><body>
+<div class="item"><span class="name">First</span></div>
+<div class="item"><span class="name">Second</span></div>
+</body></html>`))
+	if err != nil {
+		t.Fatalf("parsing fixture: %v", err)
+	}
+
+	s := &Scraper{
+		DividePage: DividePageBySelector(".item"),
+		Parts: []Part{
+			{Name: "name", Selector: ".name", Extractor: &extract.Text{}},
+		},
+	}
+	sink := &collectSink{}
+	s.Sink = sink
+
+	if err := s.extractPage(doc, 0, &RelatedURLs{}); err != nil {
+		t.Fatalf("extractPage: %v", err)
+	}
+
+	if len(sink.blocks) != 2 {
+		t.Fatalf("expected 2 blocks written to the sink, got %d", len(sink.blocks))
+	}
+	if sink.blocks[0]["name"] != "First" || sink.blocks[1]["name"] != "Second" {
+		t.Fatalf("unexpected block contents: %+v", sink.blocks)
+	}
+}
+
+func TestExtractPageFollowsPrimaryDetailsLink(t *testing.T) {
+	detailSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><p class="body">Full story</p></body></html>`))
+	}))
+	defer detailSrv.Close()
+
+	listHTML := `<html><body>
+<div class="item"><a class="link" href="` + detailSrv.URL + `">read more</a></div>
+</body></html>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(listHTML))
+	if err != nil {
+		t.Fatalf("parsing fixture: %v", err)
+	}
+
+	detailScraper := &Scraper{
+		DividePage: DividePageBySelector("body"),
+		Parts: []Part{
+			{Name: "body", Selector: ".body", Extractor: &extract.Text{}},
+		},
+	}
+
+	s := &Scraper{
+		DividePage: DividePageBySelector(".item"),
+		Parts: []Part{
+			{
+				Name:      "story",
+				Selector:  ".link",
+				Extractor: &extract.Attr{Attr: "href"},
+				Details:   detailScraper,
+				LinkTag:   LinkPrimary,
+			},
+		},
+	}
+	sink := &collectSink{}
+	s.Sink = sink
+
+	if err := s.extractPage(doc, 0, &RelatedURLs{}); err != nil {
+		t.Fatalf("extractPage: %v", err)
+	}
+
+	if len(sink.blocks) != 1 {
+		t.Fatalf("expected 1 block written to the sink, got %d", len(sink.blocks))
+	}
+	nested, ok := sink.blocks[0]["story"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected nested detail result, got %#v", sink.blocks[0]["story"])
+	}
+	if nested["body"] != "Full story" {
+		t.Fatalf("detail body = %v, want %q", nested["body"], "Full story")
+	}
+}
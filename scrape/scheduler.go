@@ -0,0 +1,238 @@
+package scrape
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+)
+
+// ErrDisallowedByRobots is returned by Scheduler.Allow, and recorded in
+// Results.Visited, when a host's robots.txt disallows the requested URL.
+var ErrDisallowedByRobots = errors.New("scrape: URL disallowed by robots.txt")
+
+// hostState is the process-wide scheduling state for one host: its cached
+// robots.txt, its current concurrency usage, a token bucket for HostQPS,
+// and the last time it was fetched (so Crawl-delay and FetchDelay can be
+// honored). Everything here is guarded by mu - there is no lock-free fast
+// path - since it's shared by every task in the process fetching from this
+// host concurrently.
+type hostState struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	robots *robotstxt.RobotsData
+
+	// active is how many fetches to this host are currently in flight.
+	// capacity is the concurrency cap most recently requested by any
+	// task's Scheduler.Allow call; it's re-applied on every call rather
+	// than fixed at first use, so a task started with a different
+	// MaxConcurrentPerHost than an earlier one still has its limit
+	// honored instead of being stuck with whichever value happened to
+	// create this hostState.
+	active   int
+	capacity int
+
+	lastFetch time.Time
+
+	tokens     float64
+	lastRefill time.Time
+}
+
+// Scheduler sits in front of every fetch routed through Allow (in Run,
+// each listing page) and enforces robots.txt, a
+// per-host concurrency cap, a per-host QPS token bucket, and the
+// configured FetchDelay/RandomizeFetchDelay. Per-host state lives in a
+// process-wide map (below), not on the Scheduler itself, so every
+// Scheduler in the process - one per in-flight task - throttles the same
+// host together instead of each task getting its own independent budget.
+type Scheduler struct {
+	Opts   ScrapeOptions
+	Client *http.Client
+}
+
+// NewScheduler returns a Scheduler configured from opts.
+func NewScheduler(opts ScrapeOptions) *Scheduler {
+	return &Scheduler{
+		Opts:   opts,
+		Client: http.DefaultClient,
+	}
+}
+
+var (
+	hostsMu sync.Mutex
+	hosts   = map[string]*hostState{}
+)
+
+// Allow blocks until rawURL may be fetched under every configured limit,
+// then returns a release func the caller must call once the fetch
+// completes, freeing its concurrency slot for the next request to the same
+// host. It returns ErrDisallowedByRobots without blocking if the URL is
+// disallowed.
+func (s *Scheduler) Allow(rawURL string) (func(), error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("scrape: scheduler parsing %s: %w", rawURL, err)
+	}
+
+	hs := s.hostStateFor(u.Host)
+
+	if s.Opts.ObeyRobots {
+		robots, err := hs.robotsData(s.Client, u)
+		if err != nil {
+			return nil, err
+		}
+		if robots != nil && !robots.TestAgent(u.Path, "dataflowkit") {
+			return nil, ErrDisallowedByRobots
+		}
+	}
+
+	release := hs.acquire(s.Opts.MaxConcurrentPerHost)
+
+	hs.waitTurn(s.Opts)
+
+	return release, nil
+}
+
+func (s *Scheduler) hostStateFor(host string) *hostState {
+	hostsMu.Lock()
+	defer hostsMu.Unlock()
+	hs, ok := hosts[host]
+	if !ok {
+		hs = &hostState{}
+		hosts[host] = hs
+	}
+	return hs
+}
+
+// acquire blocks until host has fewer than maxConcurrent fetches in
+// flight (maxConcurrent <= 0 means unlimited), then reserves a slot and
+// returns a release func to free it. The whole check-and-reserve happens
+// under hs.mu, so concurrent callers can never both observe a free slot
+// and overrun maxConcurrent.
+func (hs *hostState) acquire(maxConcurrent int) func() {
+	hs.mu.Lock()
+	if hs.cond == nil {
+		hs.cond = sync.NewCond(&hs.mu)
+	}
+	hs.capacity = maxConcurrent
+	for hs.capacity > 0 && hs.active >= hs.capacity {
+		hs.cond.Wait()
+	}
+	hs.active++
+	hs.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			hs.mu.Lock()
+			hs.active--
+			hs.cond.Signal()
+			hs.mu.Unlock()
+		})
+	}
+}
+
+// robotsData returns host's cached robots.txt, fetching and caching it on
+// first use. A fetch failure is treated as "no robots.txt restrictions",
+// matching how most polite crawlers degrade when robots.txt 404s or the
+// host is briefly unreachable.
+func (hs *hostState) robotsData(client *http.Client, u *url.URL) (*robotstxt.RobotsData, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	if hs.robots != nil {
+		return hs.robots, nil
+	}
+
+	robotsURL := fmt.Sprintf("%s://%s/robots.txt", u.Scheme, u.Host)
+	resp, err := client.Get(robotsURL)
+	if err != nil {
+		return nil, nil
+	}
+	defer resp.Body.Close()
+
+	robots, err := robotstxt.FromResponse(resp)
+	if err != nil {
+		return nil, nil
+	}
+	hs.robots = robots
+	return robots, nil
+}
+
+// waitTurn blocks until host's token bucket has a token and its
+// FetchDelay/Crawl-delay/RandomizeFetchDelay has elapsed since the last
+// fetch, then consumes a token and records the new lastFetch time. Each
+// wait duration is computed under hs.mu but slept on outside of it, so a
+// goroutine sleeping here never blocks another goroutine's acquire/release
+// - otherwise a single in-flight delayed fetch would serialize the whole
+// host regardless of MaxConcurrentPerHost.
+func (hs *hostState) waitTurn(opts ScrapeOptions) {
+	for {
+		hs.mu.Lock()
+		now := time.Now()
+
+		delay := opts.FetchDelay
+		if group := hs.crawlDelay(); group > delay {
+			delay = group
+		}
+		if opts.RandomizeFetchDelay && delay > 0 {
+			delay = delay + time.Duration(float64(delay)*0.5*jitter())
+		}
+		if wait := delay - now.Sub(hs.lastFetch); !hs.lastFetch.IsZero() && wait > 0 {
+			hs.mu.Unlock()
+			time.Sleep(wait)
+			continue
+		}
+
+		if opts.HostQPS > 0 {
+			hs.refillTokens(now, opts.HostQPS)
+			if hs.tokens < 1 {
+				wait := time.Second / time.Duration(opts.HostQPS*10+1)
+				hs.mu.Unlock()
+				time.Sleep(wait)
+				continue
+			}
+			hs.tokens--
+		}
+
+		hs.lastFetch = now
+		hs.mu.Unlock()
+		return
+	}
+}
+
+func (hs *hostState) crawlDelay() time.Duration {
+	if hs.robots == nil {
+		return 0
+	}
+	group := hs.robots.FindGroup("dataflowkit")
+	if group == nil {
+		return 0
+	}
+	return group.CrawlDelay
+}
+
+func (hs *hostState) refillTokens(now time.Time, qps float64) {
+	if hs.lastRefill.IsZero() {
+		hs.tokens = qps
+		hs.lastRefill = now
+		return
+	}
+	elapsed := now.Sub(hs.lastRefill).Seconds()
+	hs.tokens += elapsed * qps
+	if hs.tokens > qps {
+		hs.tokens = qps
+	}
+	hs.lastRefill = now
+}
+
+// jitter returns a pseudo-random value in [-1, 1) without pulling in
+// math/rand's global lock on the scheduler's hot path.
+func jitter() float64 {
+	return float64(time.Now().UnixNano()%1000)/500 - 1
+}
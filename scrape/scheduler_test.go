@@ -0,0 +1,94 @@
+package scrape
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHostStateAcquireEnforcesConcurrencyCap(t *testing.T) {
+	hs := &hostState{}
+
+	var inFlight, maxSeen int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release := hs.acquire(2)
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				max := atomic.LoadInt32(&maxSeen)
+				if n <= max || atomic.CompareAndSwapInt32(&maxSeen, max, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			release()
+		}()
+	}
+	wg.Wait()
+
+	if maxSeen > 2 {
+		t.Fatalf("expected at most 2 concurrent holders, saw %d", maxSeen)
+	}
+}
+
+func TestHostStateAcquireHonorsLatestCapacity(t *testing.T) {
+	hs := &hostState{}
+
+	releaseA := hs.acquire(2)
+	releaseB := hs.acquire(2)
+
+	done := make(chan struct{})
+	go func() {
+		// A later task requesting a larger cap for the same host should
+		// be allowed in immediately rather than being stuck behind the
+		// cap of 2 that created this hostState and is now full.
+		release := hs.acquire(3)
+		release()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("acquire with a larger capacity did not honor the updated limit")
+	}
+
+	releaseA()
+	releaseB()
+}
+
+func TestHostStateWaitTurnDoesNotBlockConcurrentAcquire(t *testing.T) {
+	hs := &hostState{}
+	opts := ScrapeOptions{FetchDelay: 200 * time.Millisecond}
+
+	// Prime lastFetch so the next waitTurn call has to sleep out the
+	// FetchDelay.
+	hs.waitTurn(opts)
+
+	done := make(chan struct{})
+	go func() {
+		hs.waitTurn(opts)
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond) // let the goroutine above start sleeping
+
+	acquired := make(chan struct{})
+	go func() {
+		release := hs.acquire(2)
+		release()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("acquire was blocked by a concurrent waitTurn sleeping on FetchDelay")
+	}
+
+	<-done
+}
@@ -10,7 +10,9 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/segmentio/ksuid"
+	"github.com/slotix/dataflowkit/archive"
 	"github.com/slotix/dataflowkit/extract"
+	"github.com/slotix/dataflowkit/fetch"
 	"github.com/slotix/dataflowkit/paginate"
 	"github.com/temoto/robotstxt"
 )
@@ -43,7 +45,42 @@ type Part struct {
 	// Extractor contains the logic on how to extract some results from the
 	// selector that is provided to this Piece.
 	Extractor extract.Extractor
-	Details   *Scraper
+
+	// Details, when set, is a nested Scraper run against the page linked
+	// to by this Part's extracted href. Its results are merged back into
+	// the parent block's result map under this Part's Name, so a listing
+	// and its detail pages can be scraped in a single task.
+	Details *Scraper
+
+	// LinkTag says what a Details link is for: LinkPrimary links are
+	// followed and scraped recursively, LinkRelated links (assets such as
+	// images or CSS) are only fetched once each per task and are never
+	// scraped. Run fetches them with a plain HTTP GET and discards the
+	// response; they are not written to the task's Archive. Ignored when
+	// Details is nil.
+	LinkTag LinkTag
+}
+
+// LinkTag classifies a link discovered inside a block so the scraper knows
+// whether to recurse into it or merely fetch it once.
+type LinkTag int
+
+const (
+	// LinkPrimary marks a link to a detail page that should be scraped
+	// with Part.Details and merged into the parent block's results.
+	LinkPrimary LinkTag = iota
+	// LinkRelated marks a link to an asset (image, stylesheet, etc.)
+	// that is fetched once per task but never scraped.
+	LinkRelated
+)
+
+// linkTagFromString maps a Payload Field's LinkTag string ("primary",
+// "related", or "" for the default) to a LinkTag.
+func linkTagFromString(s string) LinkTag {
+	if s == "related" {
+		return LinkRelated
+	}
+	return LinkPrimary
 }
 
 //Scraper struct consolidates settings for scraping task.
@@ -76,6 +113,19 @@ type Scraper struct {
 	//Opts contains options that are used during the progress of a
 	// scrape.
 	Opts ScrapeOptions
+
+	// Fetcher retrieves each page to be scraped. When nil, Run fetches
+	// pages with its http.Client instead; NewScraper sets it to a
+	// *fetch.DynamicFetcher when the Payload asks for a headless-browser
+	// render with scripted interactions.
+	Fetcher fetch.Fetcher
+
+	// Sink receives each block as soon as it's extracted, instead of the
+	// caller having to hold the whole result set in memory. Run writes to
+	// it directly; it defaults to a *Results (itself a ResultSink) when
+	// the caller doesn't set one, which keeps the in-memory Results.First
+	// / AllBlocks usage working unchanged.
+	Sink ResultSink
 }
 
 // Results describes the results of a scrape.  It contains a list of all
@@ -121,6 +171,12 @@ func (r *Results) AllBlocks() []map[string]interface{} {
 type Session struct {
 	Robots  *robotstxt.RobotsData
 	Cookies string
+
+	// Archive, when set, persists every fetched page as a WARC record
+	// and records visited URLs in a seen-set, so the scrape is
+	// replayable and a killed-and-restarted task can resume without
+	// re-fetching.
+	Archive *archive.Archive
 }
 type Task struct {
 	ID      string
@@ -142,11 +198,32 @@ func NewTask(p Payload) (task *Task, err error) {
 	}
 	//https://blog.kowalczyk.info/article/JyRZ/generating-good-random-and-unique-ids-in-go.html
 	id := ksuid.New()
+	taskID := id.String()
+	// Resuming a previously killed task reuses its original ID, so the
+	// WARC file and seen-set it already has on disk get appended to
+	// rather than started over.
+	if p.ResumeTaskID != "" {
+		taskID = p.ResumeTaskID
+	}
 
 	task = &Task{
-		ID:      id.String(),
+		ID:      taskID,
 		Scraper: scraper,
 	}
+	// The default in-memory sink is the task's own Results, so blocks Run
+	// extracts are readable through task.Results.First/AllBlocks.
+	if _, ok := scraper.Sink.(*Results); ok {
+		scraper.Sink = &task.Results
+	}
+
+	if p.ArchiveDir != "" {
+		a, err := archive.Open(p.ArchiveDir, taskID)
+		if err != nil {
+			return nil, fmt.Errorf("scrape: opening archive for task %s: %w", taskID, err)
+		}
+		task.Session.Archive = a
+	}
+
 	return task, nil
 }
 
@@ -180,15 +257,24 @@ func NewScraper(p Payload) (*Scraper, error) {
 					logger.Println(err)
 				}
 			}
+			linkPart := Part{
+				Name:      f.Name + "_link",
+				Selector:  f.Selector,
+				Extractor: l.Href,
+			}
+			if f.Details != nil {
+				details, err := NewScraper(*f.Details)
+				if err != nil {
+					return nil, fmt.Errorf("scrape: building Details scraper for field %s: %w", f.Name, err)
+				}
+				linkPart.Details = details
+				linkPart.LinkTag = linkTagFromString(f.LinkTag)
+			}
 			parts = append(parts, Part{
 				Name:      f.Name + "_text",
 				Selector:  f.Selector,
 				Extractor: l.Text,
-			}, Part{
-				Name:      f.Name + "_link",
-				Selector:  f.Selector,
-				Extractor: l.Href,
-			})
+			}, linkPart)
 			//Add selector just one time for link type
 			selectors = append(selectors, f.Selector)
 
@@ -244,11 +330,20 @@ func NewScraper(p Payload) (*Scraper, error) {
 					logger.Println(err)
 				}
 			}
-			parts = append(parts, Part{
+			part := Part{
 				Name:      f.Name,
 				Selector:  f.Selector,
 				Extractor: e,
-			})
+			}
+			if f.Details != nil {
+				details, err := NewScraper(*f.Details)
+				if err != nil {
+					return nil, fmt.Errorf("scrape: building Details scraper for field %s: %w", f.Name, err)
+				}
+				part.Details = details
+				part.LinkTag = linkTagFromString(f.LinkTag)
+			}
+			parts = append(parts, part)
 			selectors = append(selectors, f.Selector)
 			//	names = append(names, f.Name)
 		}
@@ -289,10 +384,44 @@ func NewScraper(p Payload) (*Scraper, error) {
 		dividePageFunc = DividePageByIntersection(selectors)
 	}
 
+	// A Payload carrying DynamicFetcher interactions wants its pages
+	// rendered by a headless browser instead of Run's plain HTTP fetch,
+	// so that clicks, scrolling and typed input can happen
+	// before the DOM is captured.
+	var fetcher fetch.Fetcher
+	if p.DynamicFetcher != nil {
+		fetcher = fetch.NewDynamicFetcher(*p.DynamicFetcher)
+	}
+
+	// p.Format selects which ResultSink Run streams blocks through;
+	// "json" (the default/zero value) keeps the in-memory Results sink
+	// that Results.First/AllBlocks callers already rely on.
+	var sink ResultSink
+	switch p.Format {
+	case "csv":
+		if p.Output == nil {
+			return nil, errors.New("scrape: Format \"csv\" requires Output")
+		}
+		names := make([]string, len(parts))
+		for i, part := range parts {
+			names[i] = part.Name
+		}
+		sink = &CSVSink{W: p.Output, PartNames: names}
+	case "jsonlines":
+		if p.Output == nil {
+			return nil, errors.New("scrape: Format \"jsonlines\" requires Output")
+		}
+		sink = &JSONLinesSink{W: p.Output}
+	default:
+		sink = &Results{}
+	}
+
 	scraper := &Scraper{
 		DividePage: dividePageFunc,
 		Parts:      parts,
 		Paginator:  paginator,
+		Fetcher:    fetcher,
+		Sink:       sink,
 		Opts: ScrapeOptions{
 			MaxPages:            p.Paginator.MaxPages,
 			Format:              p.Format,
@@ -0,0 +1,256 @@
+package scrape
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ResultSink receives a scrape's output one block at a time as the scrape
+// runs, instead of the caller having to hold the whole result set in
+// memory. Open is called once before the first block of a task, WriteBlock
+// once per block (pageIdx is 0-based), and Close once the scrape finishes
+// or aborts.
+type ResultSink interface {
+	Open() error
+	WriteBlock(pageIdx int, block map[string]interface{}) error
+	Close() error
+}
+
+// Open implements ResultSink. It initializes Visited and Results if they
+// are nil and leaves any existing contents in place.
+func (r *Results) Open() error {
+	if r.Visited == nil {
+		r.Visited = map[string]error{}
+	}
+	if r.Results == nil {
+		r.Results = [][]map[string]interface{}{}
+	}
+	return nil
+}
+
+// WriteBlock implements ResultSink, appending block to the page at
+// pageIdx, growing the page list as needed. This keeps Results usable as a
+// drop-in, in-memory ResultSink for callers that haven't moved to one of
+// the streaming sinks yet.
+func (r *Results) WriteBlock(pageIdx int, block map[string]interface{}) error {
+	for len(r.Results) <= pageIdx {
+		r.Results = append(r.Results, []map[string]interface{}{})
+	}
+	r.Results[pageIdx] = append(r.Results[pageIdx], block)
+	return nil
+}
+
+// Close implements ResultSink. Results has nothing to flush or release.
+func (r *Results) Close() error {
+	return nil
+}
+
+// JSONLinesSink writes one JSON-encoded block per line to W, the format
+// most pipelines expect to pipe into `jq` or load into a document store.
+type JSONLinesSink struct {
+	W   io.Writer
+	enc *json.Encoder
+}
+
+func (s *JSONLinesSink) Open() error {
+	s.enc = json.NewEncoder(s.W)
+	return nil
+}
+
+func (s *JSONLinesSink) WriteBlock(pageIdx int, block map[string]interface{}) error {
+	return s.enc.Encode(block)
+}
+
+func (s *JSONLinesSink) Close() error {
+	return nil
+}
+
+// CSVSink writes blocks as CSV rows to W, with a header row inferred from
+// PartNames the first time WriteBlock is called. Blocks missing a column
+// get an empty cell, so a ragged scrape (Parts that return nil sometimes)
+// doesn't break the header contract.
+type CSVSink struct {
+	W         io.Writer
+	PartNames []string
+
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (s *CSVSink) Open() error {
+	s.w = csv.NewWriter(s.W)
+	return nil
+}
+
+func (s *CSVSink) WriteBlock(pageIdx int, block map[string]interface{}) error {
+	if !s.wroteHeader {
+		if err := s.w.Write(s.PartNames); err != nil {
+			return err
+		}
+		s.wroteHeader = true
+	}
+
+	row := make([]string, len(s.PartNames))
+	for i, name := range s.PartNames {
+		if v, ok := block[name]; ok {
+			row[i] = fmt.Sprintf("%v", v)
+		}
+	}
+	return s.w.Write(row)
+}
+
+func (s *CSVSink) Close() error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// HTTPPostSink batches BatchSize blocks into a single JSON array and POSTs
+// it to URL, retrying failed requests up to MaxRetries times with
+// exponential backoff. A BatchSize of 0 defaults to 1 (post every block
+// immediately).
+type HTTPPostSink struct {
+	Client     *http.Client
+	URL        string
+	BatchSize  int
+	MaxRetries int
+
+	batch []map[string]interface{}
+}
+
+func (s *HTTPPostSink) Open() error {
+	if s.Client == nil {
+		s.Client = http.DefaultClient
+	}
+	if s.BatchSize <= 0 {
+		s.BatchSize = 1
+	}
+	s.batch = s.batch[:0]
+	return nil
+}
+
+func (s *HTTPPostSink) WriteBlock(pageIdx int, block map[string]interface{}) error {
+	s.batch = append(s.batch, block)
+	if len(s.batch) < s.BatchSize {
+		return nil
+	}
+	return s.flush()
+}
+
+func (s *HTTPPostSink) Close() error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+	return s.flush()
+}
+
+func (s *HTTPPostSink) flush() error {
+	body, err := json.Marshal(s.batch)
+	if err != nil {
+		return err
+	}
+	s.batch = s.batch[:0]
+
+	var lastErr error
+	backoff := 500 * time.Millisecond
+	for attempt := 0; attempt <= s.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		resp, err := s.Client.Post(s.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("sink: %s returned %s", s.URL, resp.Status)
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("sink: %s returned %s", s.URL, resp.Status)
+		}
+		return nil
+	}
+	return fmt.Errorf("sink: giving up posting to %s after %d attempts: %w", s.URL, s.MaxRetries+1, lastErr)
+}
+
+// ObjectPutter is the subset of an S3/GCS client a ObjectStoreSink needs,
+// so tests can substitute a fake without pulling in a cloud SDK.
+type ObjectPutter interface {
+	PutObject(key string, body []byte) error
+}
+
+// ObjectStoreSink writes batches of JSON-lines blocks as objects under
+// KeyPrefix, rotating to a new object once RotateSize bytes or
+// RotatePages pages have been buffered, whichever comes first. A
+// RotateSize or RotatePages of 0 disables that trigger.
+type ObjectStoreSink struct {
+	Store       ObjectPutter
+	KeyPrefix   string
+	RotateSize  int
+	RotatePages int
+
+	buf         bytes.Buffer
+	pageCount   int
+	lastPageIdx int
+	havePage    bool
+	partNum     int
+}
+
+func (s *ObjectStoreSink) Open() error {
+	s.buf.Reset()
+	s.pageCount = 0
+	s.havePage = false
+	s.partNum = 0
+	return nil
+}
+
+func (s *ObjectStoreSink) WriteBlock(pageIdx int, block map[string]interface{}) error {
+	enc, err := json.Marshal(block)
+	if err != nil {
+		return err
+	}
+	s.buf.Write(enc)
+	s.buf.WriteByte('\n')
+
+	// pageCount tracks distinct pages buffered, not blocks written: a
+	// page with many blocks must count as one page toward RotatePages,
+	// not rotate after RotatePages blocks.
+	if !s.havePage || pageIdx != s.lastPageIdx {
+		s.pageCount++
+		s.lastPageIdx = pageIdx
+		s.havePage = true
+	}
+
+	if (s.RotateSize > 0 && s.buf.Len() >= s.RotateSize) ||
+		(s.RotatePages > 0 && s.pageCount >= s.RotatePages) {
+		return s.rotate()
+	}
+	return nil
+}
+
+func (s *ObjectStoreSink) Close() error {
+	if s.buf.Len() == 0 {
+		return nil
+	}
+	return s.rotate()
+}
+
+func (s *ObjectStoreSink) rotate() error {
+	key := fmt.Sprintf("%s-%05d.jsonl", s.KeyPrefix, s.partNum)
+	if err := s.Store.PutObject(key, s.buf.Bytes()); err != nil {
+		return err
+	}
+	s.partNum++
+	s.pageCount = 0
+	s.havePage = false
+	s.buf.Reset()
+	return nil
+}
@@ -0,0 +1,47 @@
+package scrape
+
+import "testing"
+
+type fakeObjectPutter struct {
+	puts [][]byte
+}
+
+func (f *fakeObjectPutter) PutObject(key string, body []byte) error {
+	f.puts = append(f.puts, append([]byte(nil), body...))
+	return nil
+}
+
+func TestObjectStoreSinkRotatesByDistinctPagesNotBlocks(t *testing.T) {
+	store := &fakeObjectPutter{}
+	sink := &ObjectStoreSink{Store: store, KeyPrefix: "out", RotatePages: 3}
+	if err := sink.Open(); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	// 3 blocks on page 0, the old per-block counter would have hit
+	// RotatePages=3 right here and rotated early even though only 1
+	// distinct page has been seen.
+	for i := 0; i < 3; i++ {
+		if err := sink.WriteBlock(0, map[string]interface{}{"i": i}); err != nil {
+			t.Fatalf("WriteBlock: %v", err)
+		}
+	}
+	if len(store.puts) != 0 {
+		t.Fatalf("expected no rotation yet: only 1 distinct page buffered, got %d puts", len(store.puts))
+	}
+
+	if err := sink.WriteBlock(1, map[string]interface{}{"i": 3}); err != nil {
+		t.Fatalf("WriteBlock: %v", err)
+	}
+	if len(store.puts) != 0 {
+		t.Fatalf("expected no rotation yet: only 2 distinct pages buffered, got %d puts", len(store.puts))
+	}
+
+	// A block on a 3rd distinct page crosses RotatePages=3 and rotates.
+	if err := sink.WriteBlock(2, map[string]interface{}{"i": 4}); err != nil {
+		t.Fatalf("WriteBlock: %v", err)
+	}
+	if len(store.puts) != 1 {
+		t.Fatalf("expected exactly 1 rotation once a 3rd distinct page was buffered, got %d", len(store.puts))
+	}
+}